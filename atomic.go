@@ -0,0 +1,107 @@
+package fswatch
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// renameCoalesceWindow is how long AtomicSaveMode waits for the matching
+// CREATE event at a rename's destination before giving up on pairing it.
+const renameCoalesceWindow = 500 * time.Millisecond
+
+// pendingRename tracks a file that was the source of a RENAME event while
+// AtomicSaveMode is enabled, waiting to be paired with the CREATE event at
+// its destination (fsnotify does not expose the rename's destination path
+// directly, so pairing is done by watching for a CREATE in the same
+// directory within renameCoalesceWindow).
+type pendingRename struct {
+	dir string
+	at  time.Time
+}
+
+// trackPendingRename records event's source path as awaiting a paired
+// CREATE, and forgets it after renameCoalesceWindow if none arrives. If no
+// CREATE ever pairs with it, the original rename event is forwarded to the
+// source's debounce channel (the legacy, non-atomic-save behavior) so any
+// in-flight debounceFile goroutine for it still terminates instead of
+// leaking.
+func (w *Watcher) trackPendingRename(event fsnotify.Event) {
+	w.renameMu.Lock()
+	if w.pendingRenames == nil {
+		w.pendingRenames = make(map[string]pendingRename)
+	}
+	w.pendingRenames[event.Name] = pendingRename{
+		dir: filepath.Dir(event.Name),
+		at:  time.Now(),
+	}
+	w.renameMu.Unlock()
+
+	time.AfterFunc(renameCoalesceWindow, func() {
+		w.renameMu.Lock()
+		pr, stillPending := w.pendingRenames[event.Name]
+		if stillPending {
+			delete(w.pendingRenames, event.Name)
+		}
+		w.renameMu.Unlock()
+
+		if !stillPending || time.Since(pr.at) < renameCoalesceWindow {
+			// Either a CREATE already paired with this rename (consumed
+			// by resolvePendingRename) or a newer rename of the same
+			// source path superseded this one; either way, nothing left
+			// to do here.
+			return
+		}
+
+		w.debounceMapMu.Lock()
+		ch, ok := w.debounceMap[event.Name]
+		w.debounceMapMu.Unlock()
+
+		if ok {
+			ch <- event
+		}
+	})
+}
+
+// resolvePendingRename reports whether event (a CREATE) pairs with a
+// pending rename in the same directory within renameCoalesceWindow,
+// consuming the pending entry if so.
+func (w *Watcher) resolvePendingRename(event fsnotify.Event) (source string, ok bool) {
+	dir := filepath.Dir(event.Name)
+
+	w.renameMu.Lock()
+	defer w.renameMu.Unlock()
+
+	for name, pr := range w.pendingRenames {
+		if pr.dir != dir || time.Since(pr.at) > renameCoalesceWindow {
+			continue
+		}
+		delete(w.pendingRenames, name)
+		return name, true
+	}
+
+	return "", false
+}
+
+// reattributeDebounce moves any in-flight debounce for source onto dest
+// and nudges it so the coalesced atomic save is treated as a fresh write
+// to the final name. If source had no in-flight debounce (e.g. its write
+// already fired before the rename), it still emits a single WRITE for
+// dest rather than swallowing the save entirely.
+func (w *Watcher) reattributeDebounce(source, dest string) {
+	w.debounceMapMu.Lock()
+	ch, ok := w.debounceMap[source]
+	if ok {
+		delete(w.debounceMap, source)
+		w.debounceMap[dest] = ch
+	}
+	w.debounceMapMu.Unlock()
+
+	if ok {
+		ch <- fsnotify.Event{Name: dest, Op: fsnotify.Write}
+		return
+	}
+
+	w.handleEvent(fsnotify.Event{Name: dest, Op: fsnotify.Write})
+}
@@ -0,0 +1,54 @@
+package fswatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAtomicSaveModeCoalescesRepeatedSaves is a regression test for a
+// deadlock: forwarding a RENAME into the source's debounce channel (the
+// legacy rename handling) raced reattributeDebounce's synthetic WRITE for
+// the destination, so the coalesced event was silently lost and
+// debounceMap[dest] was left pointing at a channel nobody drained. Enough
+// repeated atomic saves to the same filename would eventually fill that
+// channel's buffer and hang the whole watcher.
+func TestAtomicSaveModeCoalescesRepeatedSaves(t *testing.T) {
+	setup(t)
+	defer teardown(t)
+
+	w, err := NewWatcher(200 * time.Millisecond)
+	assert.Nil(t, err)
+	w.AtomicSaveMode = true
+
+	err = w.Add(TestfolderPath)
+	assert.Nil(t, err)
+
+	tmpPath := TestfolderPath + "/file.txt.tmp"
+	// fsnotify reports events with a cleaned path, so compare against the
+	// cleaned form rather than the "./"-prefixed TestfolderPath constant.
+	finalPath := filepath.Clean(TestfolderPath + "/file.txt")
+
+	for i := 0; i < channelBufferSize+3; i++ {
+		f, err := os.Create(tmpPath)
+		assert.Nil(t, err)
+		_, err = f.WriteString("content\n")
+		assert.Nil(t, err)
+		assert.Nil(t, f.Close())
+
+		assert.Nil(t, os.Rename(tmpPath, finalPath))
+
+		select {
+		case event := <-w.Events:
+			assert.Equal(t, finalPath, event.Name)
+		case <-time.After(time.Second * 2):
+			assert.FailNow(t, "no coalesced event received for atomic save")
+		}
+	}
+
+	assert.Nil(t, w.Remove(TestfolderPath))
+	assert.Nil(t, w.Close())
+}
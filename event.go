@@ -0,0 +1,12 @@
+package fswatch
+
+import "github.com/fsnotify/fsnotify"
+
+// Event wraps an fsnotify.Event with metadata computed by the watcher.
+type Event struct {
+	fsnotify.Event
+
+	// Hash is the content hash computed for this event's file, set only
+	// when DedupByHash is true and hashing succeeded.
+	Hash []byte
+}
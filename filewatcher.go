@@ -0,0 +1,33 @@
+package fswatch
+
+import "github.com/fsnotify/fsnotify"
+
+// FileWatcher is the interface implemented by the backends that Watcher
+// debounces events for. It abstracts over fsnotify.Watcher so that Watcher
+// can run on top of either native OS filesystem notifications or the
+// polling fallback provided by NewPollingWatcher.
+type FileWatcher interface {
+	// Events returns the channel on which raw filesystem events are published.
+	Events() chan fsnotify.Event
+
+	// Errors returns the channel on which backend errors are published.
+	Errors() chan error
+
+	// Add starts watching the named file or directory.
+	Add(name string) error
+
+	// Remove stops watching the named file or directory.
+	Remove(name string) error
+
+	// Close releases all resources held by the backend.
+	Close() error
+}
+
+// fsNotifyWatcher adapts *fsnotify.Watcher to the FileWatcher interface.
+type fsNotifyWatcher struct {
+	*fsnotify.Watcher
+}
+
+func (f *fsNotifyWatcher) Events() chan fsnotify.Event { return f.Watcher.Events }
+
+func (f *fsNotifyWatcher) Errors() chan error { return f.Watcher.Errors }
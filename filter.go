@@ -0,0 +1,65 @@
+package fswatch
+
+import "github.com/fsnotify/fsnotify"
+
+// Include restricts the watcher to only emit events for paths matching at
+// least one of the given doublestar-style glob patterns (e.g. "**/*.go").
+// If no include patterns are set, every path is eligible. Exclude patterns
+// take precedence over Include patterns.
+func (w *Watcher) Include(patterns []string) {
+	w.filterMu.Lock()
+	w.includes = patterns
+	w.filterMu.Unlock()
+}
+
+// Exclude discards events for paths matching any of the given
+// doublestar-style glob patterns (e.g. "**/*.swp", "**/node_modules/**"),
+// even if they also match an Include pattern.
+func (w *Watcher) Exclude(patterns []string) {
+	w.filterMu.Lock()
+	w.excludes = patterns
+	w.filterMu.Unlock()
+}
+
+// SetOpMask restricts the watcher to only emit events whose Op has at
+// least one bit set in mask, e.g. fsnotify.Write|fsnotify.Create. The zero
+// value (the default) disables masking and emits events for every Op.
+func (w *Watcher) SetOpMask(mask fsnotify.Op) {
+	w.filterMu.Lock()
+	w.opMask = mask
+	w.filterMu.Unlock()
+}
+
+// passesFilter reports whether event should be processed further. It
+// applies the Op mask first, then Exclude and Include glob patterns, and
+// runs before the event is inserted into debounceMap so filtered events
+// never consume a debounce goroutine.
+func (w *Watcher) passesFilter(event fsnotify.Event) bool {
+	w.filterMu.Lock()
+	includes := w.includes
+	excludes := w.excludes
+	mask := w.opMask
+	w.filterMu.Unlock()
+
+	if mask != 0 && event.Op&mask == 0 {
+		return false
+	}
+
+	for _, pattern := range excludes {
+		if matchGlob(pattern, event.Name) {
+			return false
+		}
+	}
+
+	if len(includes) == 0 {
+		return true
+	}
+
+	for _, pattern := range includes {
+		if matchGlob(pattern, event.Name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,91 @@
+package fswatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncludeExcludeFiltering(t *testing.T) {
+	setup(t)
+	defer teardown(t)
+
+	w, err := NewWatcher(50 * time.Millisecond)
+	assert.Nil(t, err)
+	defer w.Close()
+
+	assert.Nil(t, w.Add(TestfolderPath))
+
+	w.Include([]string{"**/*.go"})
+	w.Exclude([]string{"**/*_test.go"})
+
+	ignoredPath := TestfolderPath + "/ignored.txt"
+	f, err := os.Create(ignoredPath)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	excludedPath := TestfolderPath + "/main_test.go"
+	f, err = os.Create(excludedPath)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	// fsnotify reports events with a cleaned path, so compare against the
+	// cleaned form rather than the "./"-prefixed TestfolderPath constant.
+	matchingPath := filepath.Clean(TestfolderPath + "/main.go")
+	f, err = os.Create(matchingPath)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	select {
+	case event := <-w.Events:
+		assert.Equal(t, matchingPath, event.Name)
+	case <-time.After(time.Second * 2):
+		assert.FailNow(t, "no event received for included file")
+	}
+
+	// Nothing else should follow; ignored.txt and main_test.go must never
+	// have entered the debounce map in the first place.
+	select {
+	case event := <-w.Events:
+		assert.FailNow(t, "unexpected event for filtered-out file", event.Name)
+	case <-time.After(time.Millisecond * 200):
+	}
+}
+
+func TestSetOpMask(t *testing.T) {
+	setup(t)
+	defer teardown(t)
+
+	w, err := NewWatcher(50 * time.Millisecond)
+	assert.Nil(t, err)
+	defer w.Close()
+
+	assert.Nil(t, w.Add(TestfolderPath))
+
+	// Only allow REMOVE events through.
+	w.SetOpMask(fsnotify.Remove)
+
+	filePath := filepath.Clean(TestfolderPath + "/file.txt")
+	f, err := os.Create(filePath)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	select {
+	case event := <-w.Events:
+		assert.FailNow(t, "unexpected event while Op mask excludes CREATE", event.Name)
+	case <-time.After(time.Millisecond * 300):
+	}
+
+	assert.Nil(t, os.Remove(filePath))
+
+	select {
+	case event := <-w.Events:
+		assert.Equal(t, filePath, event.Name)
+	case <-time.After(time.Second * 2):
+		assert.FailNow(t, "no event received for REMOVE despite matching Op mask")
+	}
+}
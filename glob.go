@@ -0,0 +1,50 @@
+package fswatch
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// matchGlob reports whether name matches pattern using shell-style
+// wildcards as supported by filepath.Match, with the addition of "**" to
+// match zero or more path segments (e.g. "**/*.go" or "vendor/**"), in the
+// style of doublestar globs.
+func matchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "**") {
+		ok, _ := filepath.Match(pattern, name)
+		return ok
+	}
+
+	patternParts := strings.Split(filepath.ToSlash(pattern), "/")
+	nameParts := strings.Split(filepath.ToSlash(name), "/")
+
+	return matchGlobParts(patternParts, nameParts)
+}
+
+// matchGlobParts recursively matches path segments, treating "**" as a
+// wildcard for any number (including zero) of segments.
+func matchGlobParts(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	if pattern[0] == "**" {
+		if matchGlobParts(pattern[1:], name) {
+			return true
+		}
+		if len(name) == 0 {
+			return false
+		}
+		return matchGlobParts(pattern, name[1:])
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+
+	if ok, _ := filepath.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+
+	return matchGlobParts(pattern[1:], name[1:])
+}
@@ -0,0 +1,66 @@
+package fswatch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultHashCacheCapacity bounds the number of paths DedupByHash remembers
+// hashes for, to keep memory use flat when watching large trees.
+const defaultHashCacheCapacity = 1024
+
+// defaultHasher streams the file at path through SHA-256.
+func defaultHasher(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
+// hashEvent computes the content hash for event when DedupByHash is
+// enabled, and reports whether the event should still be emitted. Hashing
+// failures (e.g. the file vanished before it could be read) fall back to
+// emitting the event unchanged rather than silently dropping a real change.
+func (w *Watcher) hashEvent(event fsnotify.Event) (hash []byte, emit bool) {
+	if !w.DedupByHash || !(isWrite(event) || isCreate(event)) {
+		return nil, true
+	}
+
+	hasher := w.Hasher
+	if hasher == nil {
+		hasher = defaultHasher
+	}
+
+	sum, err := hasher(event.Name)
+	if err != nil {
+		return nil, true
+	}
+
+	w.hashMu.Lock()
+	defer w.hashMu.Unlock()
+
+	if w.hashCache == nil {
+		w.hashCache = newHashLRU(defaultHashCacheCapacity)
+	}
+
+	prev, ok := w.hashCache.get(event.Name)
+	w.hashCache.set(event.Name, sum)
+
+	if ok && bytes.Equal(prev, sum) {
+		return sum, false
+	}
+
+	return sum, true
+}
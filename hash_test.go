@@ -0,0 +1,67 @@
+package fswatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupByHashSuppressesUnchangedContent(t *testing.T) {
+	setup(t)
+	defer teardown(t)
+
+	w, err := NewWatcher(50 * time.Millisecond)
+	assert.Nil(t, err)
+	defer w.Close()
+
+	w.DedupByHash = true
+
+	assert.Nil(t, w.Add(TestfolderPath))
+
+	// fsnotify reports events with a cleaned path, so compare against the
+	// cleaned form rather than the "./"-prefixed TestfolderPath constant.
+	filePath := filepath.Clean(TestfolderPath + "/dedup.txt")
+
+	writeFile := func(content string) {
+		f, err := os.Create(filePath)
+		assert.Nil(t, err)
+		_, err = f.WriteString(content)
+		assert.Nil(t, err)
+		assert.Nil(t, f.Close())
+	}
+
+	writeFile("hello\n")
+
+	var firstHash []byte
+	select {
+	case event := <-w.Events:
+		assert.Equal(t, filePath, event.Name)
+		assert.NotEmpty(t, event.Hash)
+		firstHash = event.Hash
+	case <-time.After(time.Second * 2):
+		assert.FailNow(t, "no event received for initial write")
+	}
+
+	// Rewriting identical content should be suppressed entirely.
+	writeFile("hello\n")
+
+	select {
+	case event := <-w.Events:
+		assert.FailNow(t, "unexpected event for unchanged content", event.Name)
+	case <-time.After(time.Millisecond * 300):
+	}
+
+	// Changing the content should emit again, with a different hash.
+	writeFile("hello again\n")
+
+	select {
+	case event := <-w.Events:
+		assert.Equal(t, filePath, event.Name)
+		assert.NotEqual(t, firstHash, event.Hash)
+	case <-time.After(time.Second * 2):
+		assert.FailNow(t, "no event received for changed content")
+	}
+}
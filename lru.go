@@ -0,0 +1,56 @@
+package fswatch
+
+import "container/list"
+
+// hashLRU is a fixed-capacity LRU cache mapping a file path to the last
+// content hash emitted for it. It bounds memory use for DedupByHash when
+// watching large trees. It is not safe for concurrent use; callers
+// serialize access (Watcher does so via hashMu).
+type hashLRU struct {
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type hashLRUEntry struct {
+	path string
+	hash []byte
+}
+
+func newHashLRU(capacity int) *hashLRU {
+	return &hashLRU{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *hashLRU) get(path string) ([]byte, bool) {
+	el, ok := c.items[path]
+	if !ok {
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+
+	return el.Value.(*hashLRUEntry).hash, true
+}
+
+func (c *hashLRU) set(path string, hash []byte) {
+	if el, ok := c.items[path]; ok {
+		el.Value.(*hashLRUEntry).hash = hash
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	el := c.ll.PushFront(&hashLRUEntry{path: path, hash: hash})
+	c.items[path] = el
+
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*hashLRUEntry).path)
+		}
+	}
+}
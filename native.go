@@ -0,0 +1,17 @@
+//go:build !plan9
+// +build !plan9
+
+package fswatch
+
+import "github.com/fsnotify/fsnotify"
+
+// newNativeWatcher creates a FileWatcher backed by the operating system's
+// native filesystem notification API (inotify, kqueue, ReadDirectoryChangesW, ...).
+func newNativeWatcher() (FileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	return &fsNotifyWatcher{watcher}, nil
+}
@@ -0,0 +1,12 @@
+//go:build plan9
+// +build plan9
+
+package fswatch
+
+import "errors"
+
+// newNativeWatcher always fails on plan9, where fsnotify has no backend.
+// Callers go through New(), which falls back to NewPollingWatcher.
+func newNativeWatcher() (FileWatcher, error) {
+	return nil, errors.New("fswatch: no native filesystem notification backend on this platform")
+}
@@ -0,0 +1,233 @@
+package fswatch
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// pollState is the subset of os.FileInfo (plus the platform-specific
+// inode/Qid.Path) compared between poll ticks to synthesize fsnotify
+// events for a path. Including the inode catches a file replaced in-place
+// whose size, mode and mtime happen to come out unchanged, e.g. a rename
+// onto the same path on a filesystem with coarse mtime resolution.
+type pollState struct {
+	mode    os.FileMode
+	size    int64
+	modTime time.Time
+	inode   uint64
+}
+
+func statToPollState(info os.FileInfo) pollState {
+	return pollState{
+		mode:    info.Mode(),
+		size:    info.Size(),
+		modTime: info.ModTime(),
+		inode:   inodeOf(info),
+	}
+}
+
+// pollingWatcher is a FileWatcher that periodically walks the watched paths
+// and diffs file metadata (mtime, size, mode, inode) to synthesize fsnotify.Event
+// values. It is used as a fallback on platforms and filesystems where
+// native notifications are unavailable or unreliable, e.g. plan9, NFS/SMB
+// mounts, and some container overlay filesystems.
+type pollingWatcher struct {
+	interval time.Duration
+
+	mu    sync.Mutex
+	paths map[string]struct{}
+	state map[string]pollState
+
+	events    chan fsnotify.Event
+	errors    chan error
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPollingWatcher creates a FileWatcher that polls watched paths every
+// interval instead of relying on native OS filesystem notifications.
+func NewPollingWatcher(interval time.Duration) (FileWatcher, error) {
+	w := &pollingWatcher{
+		interval: interval,
+		paths:    make(map[string]struct{}),
+		state:    make(map[string]pollState),
+		events:   make(chan fsnotify.Event),
+		errors:   make(chan error),
+		closeCh:  make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+func (w *pollingWatcher) Events() chan fsnotify.Event { return w.events }
+
+func (w *pollingWatcher) Errors() chan error { return w.errors }
+
+// Add registers name (a file or directory) to be polled for changes. name
+// is canonicalized with filepath.Clean so it compares equal to the
+// derived child paths (filepath.Join/filepath.Dir) used while diffing.
+func (w *pollingWatcher) Add(name string) error {
+	name = filepath.Clean(name)
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+
+	// Record a directory's current entries as the baseline, so only entries
+	// that appear, change or vanish after Add are reported.
+	children := make(map[string]pollState)
+	if entries, err := os.ReadDir(name); err == nil {
+		for _, entry := range entries {
+			child := filepath.Join(name, entry.Name())
+			if childInfo, err := os.Stat(child); err == nil {
+				children[child] = statToPollState(childInfo)
+			}
+		}
+	}
+
+	w.mu.Lock()
+	w.paths[name] = struct{}{}
+	w.state[name] = statToPollState(info)
+	for child, st := range children {
+		w.state[child] = st
+	}
+	w.mu.Unlock()
+
+	return nil
+}
+
+// Remove stops polling name.
+func (w *pollingWatcher) Remove(name string) error {
+	name = filepath.Clean(name)
+
+	w.mu.Lock()
+	delete(w.paths, name)
+	delete(w.state, name)
+	w.mu.Unlock()
+
+	return nil
+}
+
+// Close stops the polling loop.
+func (w *pollingWatcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.closeCh)
+	})
+
+	return nil
+}
+
+func (w *pollingWatcher) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.closeCh:
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+func (w *pollingWatcher) poll() {
+	w.mu.Lock()
+	paths := make([]string, 0, len(w.paths))
+	for p := range w.paths {
+		paths = append(paths, p)
+	}
+	w.mu.Unlock()
+
+	for _, path := range paths {
+		w.pollPath(path)
+	}
+}
+
+// pollPath diffs a single watched path. Directories are diffed by listing
+// their immediate children (so new/removed entries surface as CREATE and
+// REMOVE); anything else is diffed as a single file.
+func (w *pollingWatcher) pollPath(path string) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		w.diffFile(path)
+		return
+	}
+
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+		seen[child] = struct{}{}
+		w.diffFile(child)
+	}
+
+	w.mu.Lock()
+	var vanished []string
+	for known := range w.state {
+		if filepath.Dir(known) != path {
+			continue
+		}
+		if _, ok := seen[known]; !ok {
+			vanished = append(vanished, known)
+		}
+	}
+	for _, known := range vanished {
+		delete(w.state, known)
+	}
+	w.mu.Unlock()
+
+	for _, known := range vanished {
+		w.emit(fsnotify.Event{Name: known, Op: fsnotify.Remove})
+	}
+}
+
+func (w *pollingWatcher) diffFile(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			w.mu.Lock()
+			_, existed := w.state[path]
+			delete(w.state, path)
+			w.mu.Unlock()
+
+			if existed {
+				w.emit(fsnotify.Event{Name: path, Op: fsnotify.Remove})
+			}
+			return
+		}
+
+		select {
+		case w.errors <- err:
+		case <-w.closeCh:
+		}
+		return
+	}
+
+	next := statToPollState(info)
+
+	w.mu.Lock()
+	prev, existed := w.state[path]
+	w.state[path] = next
+	w.mu.Unlock()
+
+	switch {
+	case !existed:
+		w.emit(fsnotify.Event{Name: path, Op: fsnotify.Create})
+	case prev != next:
+		w.emit(fsnotify.Event{Name: path, Op: fsnotify.Write})
+	}
+}
+
+func (w *pollingWatcher) emit(event fsnotify.Event) {
+	select {
+	case w.events <- event:
+	case <-w.closeCh:
+	}
+}
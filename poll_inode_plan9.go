@@ -0,0 +1,19 @@
+//go:build plan9
+// +build plan9
+
+package fswatch
+
+import (
+	"os"
+	"syscall"
+)
+
+// inodeOf returns the Qid.Path backing info, plan9's equivalent of an
+// inode number, for the same reason as the unix implementation.
+func inodeOf(info os.FileInfo) uint64 {
+	if dir, ok := info.Sys().(*syscall.Dir); ok {
+		return dir.Qid.Path
+	}
+
+	return 0
+}
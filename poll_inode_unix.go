@@ -0,0 +1,21 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package fswatch
+
+import (
+	"os"
+	"syscall"
+)
+
+// inodeOf returns the inode number backing info, so the polling watcher
+// can detect a file replaced in-place that leaves size, mode and mtime
+// unchanged, e.g. an atomic rename onto the same path on a filesystem
+// with coarse mtime resolution.
+func inodeOf(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino)
+	}
+
+	return 0
+}
@@ -0,0 +1,13 @@
+//go:build windows
+// +build windows
+
+package fswatch
+
+import "os"
+
+// inodeOf has no cheap equivalent on windows via os.FileInfo (it would
+// require opening the file and calling GetFileInformationByHandle), so the
+// polling watcher falls back to comparing mode, size and mtime only.
+func inodeOf(os.FileInfo) uint64 {
+	return 0
+}
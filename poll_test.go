@@ -0,0 +1,64 @@
+package fswatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollingWatcher(t *testing.T) {
+	setup(t)
+	defer teardown(t)
+
+	fw, err := NewPollingWatcher(20 * time.Millisecond)
+	assert.Nil(t, err)
+	defer fw.Close()
+
+	err = fw.Add(TestfolderPath)
+	assert.Nil(t, err)
+
+	// Add canonicalizes the registered path, and every derived child path
+	// (filepath.Join) comes out clean too, so compare against the cleaned
+	// form rather than the "./"-prefixed TestfolderPath constant.
+	filePath := filepath.Clean(TestfolderPath + "/polled.txt")
+
+	f, err := os.Create(filePath)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	select {
+	case event := <-fw.Events():
+		assert.Equal(t, filePath, event.Name)
+		assert.True(t, event.Op&fsnotify.Create == fsnotify.Create)
+	case <-time.After(time.Second):
+		assert.FailNow(t, "no CREATE event received for new file")
+	}
+
+	f, err = os.OpenFile(filePath, os.O_WRONLY|os.O_APPEND, os.ModePerm)
+	assert.Nil(t, err)
+	_, err = f.WriteString("more content\n")
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	select {
+	case event := <-fw.Events():
+		assert.Equal(t, filePath, event.Name)
+		assert.True(t, event.Op&fsnotify.Write == fsnotify.Write)
+	case <-time.After(time.Second):
+		assert.FailNow(t, "no WRITE event received for modified file")
+	}
+
+	assert.Nil(t, os.Remove(filePath))
+
+	select {
+	case event := <-fw.Events():
+		assert.Equal(t, filePath, event.Name)
+		assert.True(t, event.Op&fsnotify.Remove == fsnotify.Remove)
+	case <-time.After(time.Second):
+		assert.FailNow(t, "no REMOVE event received for deleted file")
+	}
+}
@@ -0,0 +1,300 @@
+package fswatch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// dirRenameCoalesceWindow is how long AddRecursive waits for the matching
+// CREATE event at a renamed directory's destination before giving up on
+// re-attributing its pending debounces there.
+const dirRenameCoalesceWindow = 500 * time.Millisecond
+
+// pendingDirRename tracks a recursively watched directory that was the
+// source of a RENAME event, waiting to be paired with the CREATE event at
+// its destination (fsnotify does not expose the rename's destination path
+// directly, so pairing is done by watching for a directory CREATE in the
+// same parent within dirRenameCoalesceWindow) so that debounces for files
+// under it can be moved to the new location instead of dropped.
+type pendingDirRename struct {
+	parentDir string
+	debounced map[string]chan fsnotify.Event
+	at        time.Time
+}
+
+// AddRecursive registers path and every subdirectory beneath it (subject to
+// MaxDepth and IgnoreFunc) with the watcher. Subdirectories created later
+// inside the tree are registered automatically as they appear; see
+// maybeWatchNewDir. path is canonicalized with filepath.Clean so the keys
+// recorded in recursiveDirs compare equal to the cleaned paths fsnotify
+// and filepath.WalkDir report for everything beneath it.
+func (w *Watcher) AddRecursive(path string) error {
+	return w.addRecursiveFrom(filepath.Clean(path), 0)
+}
+
+// RemoveRecursive unregisters path and every subdirectory under it that was
+// previously registered via AddRecursive (or auto-registered afterwards).
+func (w *Watcher) RemoveRecursive(path string) error {
+	path = filepath.Clean(path)
+
+	w.recursiveMu.Lock()
+	var dirs []string
+	for dir := range w.recursiveDirs {
+		if dir == path || strings.HasPrefix(dir, path+string(filepath.Separator)) {
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, dir := range dirs {
+		delete(w.recursiveDirs, dir)
+	}
+	w.recursiveMu.Unlock()
+
+	var firstErr error
+	for _, dir := range dirs {
+		if err := w.Remove(dir); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// addRecursiveFrom walks root, registering every directory whose depth
+// relative to root (plus baseDepth) does not exceed MaxDepth.
+func (w *Watcher) addRecursiveFrom(root string, baseDepth int) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if w.IgnoreFunc != nil && w.IgnoreFunc(p) {
+			return filepath.SkipDir
+		}
+
+		depth := baseDepth + relDepth(root, p)
+		if w.MaxDepth >= 0 && depth > w.MaxDepth {
+			return filepath.SkipDir
+		}
+
+		if err := w.Add(p); err != nil {
+			return err
+		}
+
+		w.recursiveMu.Lock()
+		w.recursiveDirs[p] = depth
+		w.recursiveMu.Unlock()
+
+		return nil
+	})
+}
+
+// relDepth reports how many directory levels path is below root.
+func relDepth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// maybeWatchNewDir auto-registers a newly created directory when it falls
+// inside a tree previously registered via AddRecursive.
+func (w *Watcher) maybeWatchNewDir(event fsnotify.Event) {
+	parent := filepath.Dir(event.Name)
+
+	w.recursiveMu.Lock()
+	parentDepth, ok := w.recursiveDirs[parent]
+	w.recursiveMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	info, err := os.Stat(event.Name)
+	if err != nil || !info.IsDir() {
+		return
+	}
+
+	if w.IgnoreFunc != nil && w.IgnoreFunc(event.Name) {
+		return
+	}
+
+	depth := parentDepth + 1
+	if w.MaxDepth >= 0 && depth > w.MaxDepth {
+		return
+	}
+
+	// The new directory may already contain files or subdirectories, e.g.
+	// a tree copied in with `cp -r`, so walk it to pick those up too.
+	if err := w.addRecursiveFrom(event.Name, depth); err != nil {
+		w.Errors <- err
+	}
+}
+
+// maybeUnwatchRemovedDir drops bookkeeping for a directory removed from a
+// recursively watched tree.
+func (w *Watcher) maybeUnwatchRemovedDir(event fsnotify.Event) {
+	w.recursiveMu.Lock()
+	_, ok := w.recursiveDirs[event.Name]
+	if ok {
+		delete(w.recursiveDirs, event.Name)
+	}
+	w.recursiveMu.Unlock()
+
+	if ok {
+		// The path is already gone, so the backend may error; that's fine.
+		_ = w.Remove(event.Name)
+	}
+}
+
+// handleRecursiveRename drops bookkeeping for a directory (and its
+// subtree) that was renamed away from a recursively watched location, and
+// hands any pending debounces for files under it to trackPendingDirRename
+// so they can be re-attributed to the destination if a paired CREATE
+// arrives (see maybeResolveDirRename), or released otherwise.
+func (w *Watcher) handleRecursiveRename(event fsnotify.Event) {
+	w.recursiveMu.Lock()
+	_, tracked := w.recursiveDirs[event.Name]
+	var stale []string
+	if tracked {
+		for dir := range w.recursiveDirs {
+			if dir == event.Name || strings.HasPrefix(dir, event.Name+string(filepath.Separator)) {
+				stale = append(stale, dir)
+			}
+		}
+		for _, dir := range stale {
+			delete(w.recursiveDirs, dir)
+		}
+	}
+	w.recursiveMu.Unlock()
+
+	if !tracked {
+		return
+	}
+
+	_ = w.Remove(event.Name)
+
+	w.debounceMapMu.Lock()
+	pending := make(map[string]chan fsnotify.Event)
+	for path, ch := range w.debounceMap {
+		if path == event.Name || strings.HasPrefix(path, event.Name+string(filepath.Separator)) {
+			pending[path] = ch
+		}
+	}
+	w.debounceMapMu.Unlock()
+
+	w.trackPendingDirRename(event.Name, filepath.Dir(event.Name), pending)
+}
+
+// trackPendingDirRename records oldDir's pending file debounces as awaiting
+// a paired directory CREATE, and releases them after dirRenameCoalesceWindow
+// if none arrives: a RENAME event for each file's own path is sent to its
+// debounce channel (the legacy, non-reattributed behavior) so any in-flight
+// debounceFile goroutine for it still terminates instead of leaking.
+func (w *Watcher) trackPendingDirRename(oldDir, parentDir string, pending map[string]chan fsnotify.Event) {
+	if len(pending) == 0 {
+		return
+	}
+
+	w.dirRenameMu.Lock()
+	if w.pendingDirRenames == nil {
+		w.pendingDirRenames = make(map[string]pendingDirRename)
+	}
+	w.pendingDirRenames[oldDir] = pendingDirRename{
+		parentDir: parentDir,
+		debounced: pending,
+		at:        time.Now(),
+	}
+	w.dirRenameMu.Unlock()
+
+	time.AfterFunc(dirRenameCoalesceWindow, func() {
+		w.dirRenameMu.Lock()
+		pr, stillPending := w.pendingDirRenames[oldDir]
+		if stillPending {
+			delete(w.pendingDirRenames, oldDir)
+		}
+		w.dirRenameMu.Unlock()
+
+		if !stillPending || time.Since(pr.at) < dirRenameCoalesceWindow {
+			// Either a CREATE already paired with this rename (consumed
+			// by resolvePendingDirRename) or a newer rename of the same
+			// directory superseded this one.
+			return
+		}
+
+		for path, ch := range pr.debounced {
+			ch <- fsnotify.Event{Name: path, Op: fsnotify.Rename}
+		}
+	})
+}
+
+// maybeResolveDirRename pairs event (a directory CREATE) with a pending
+// directory rename in the same parent, and re-attributes any debounces
+// for files under the old directory to their new location under event.Name.
+func (w *Watcher) maybeResolveDirRename(event fsnotify.Event) {
+	info, err := os.Stat(event.Name)
+	if err != nil || !info.IsDir() {
+		return
+	}
+
+	oldDir, pending, ok := w.resolvePendingDirRename(event.Name)
+	if !ok {
+		return
+	}
+
+	w.reattributeDirDebounce(oldDir, event.Name, pending)
+}
+
+// resolvePendingDirRename reports whether newPath (a directory CREATE)
+// pairs with a pending directory rename in the same parent within
+// dirRenameCoalesceWindow, consuming the pending entry if so.
+func (w *Watcher) resolvePendingDirRename(newPath string) (oldDir string, pending map[string]chan fsnotify.Event, ok bool) {
+	parent := filepath.Dir(newPath)
+
+	w.dirRenameMu.Lock()
+	defer w.dirRenameMu.Unlock()
+
+	for dir, pr := range w.pendingDirRenames {
+		if pr.parentDir != parent || time.Since(pr.at) > dirRenameCoalesceWindow {
+			continue
+		}
+		delete(w.pendingDirRenames, dir)
+		return dir, pr.debounced, true
+	}
+
+	return "", nil, false
+}
+
+// reattributeDirDebounce moves each pending debounce from its path under
+// oldDir to the equivalent path under newDir, and nudges it so the moved
+// file's debounceFile goroutine emits using the new name once it fires.
+func (w *Watcher) reattributeDirDebounce(oldDir, newDir string, pending map[string]chan fsnotify.Event) {
+	type move struct {
+		newPath string
+		ch      chan fsnotify.Event
+	}
+
+	w.debounceMapMu.Lock()
+	moves := make([]move, 0, len(pending))
+	for oldPath, ch := range pending {
+		newPath := newDir + strings.TrimPrefix(oldPath, oldDir)
+		delete(w.debounceMap, oldPath)
+		w.debounceMap[newPath] = ch
+		moves = append(moves, move{newPath: newPath, ch: ch})
+	}
+	w.debounceMapMu.Unlock()
+
+	for _, m := range moves {
+		m.ch <- fsnotify.Event{Name: m.newPath, Op: fsnotify.Write}
+	}
+}
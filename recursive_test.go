@@ -0,0 +1,147 @@
+package fswatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddRecursiveRegistersSubdirectoriesAndAutoAdds(t *testing.T) {
+	setup(t)
+	defer teardown(t)
+
+	subDir := filepath.Clean(TestfolderPath + "/sub")
+	assert.Nil(t, os.Mkdir(subDir, os.ModePerm))
+
+	w, err := NewWatcher(100 * time.Millisecond)
+	assert.Nil(t, err)
+
+	assert.Nil(t, w.AddRecursive(TestfolderPath))
+
+	// An existing subdirectory should already be registered.
+	w.recursiveMu.Lock()
+	_, ok := w.recursiveDirs[subDir]
+	w.recursiveMu.Unlock()
+	assert.True(t, ok)
+
+	// A directory created afterwards should be auto-registered, and a
+	// file written inside it should be picked up.
+	newDir := filepath.Clean(TestfolderPath + "/new")
+	assert.Nil(t, os.Mkdir(newDir, os.ModePerm))
+
+	time.Sleep(time.Millisecond * 200)
+
+	w.recursiveMu.Lock()
+	_, ok = w.recursiveDirs[newDir]
+	w.recursiveMu.Unlock()
+	assert.True(t, ok)
+
+	// Drain the debounced CREATE event for newDir itself before looking at
+	// the one for the file written inside it.
+	select {
+	case event := <-w.Events:
+		assert.Equal(t, newDir, event.Name)
+	case <-time.After(time.Second * 2):
+		assert.FailNow(t, "no event received for auto-registered directory creation")
+	}
+
+	filePath := filepath.Clean(newDir + "/inside.txt")
+	f, err := os.Create(filePath)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	select {
+	case event := <-w.Events:
+		assert.Equal(t, filePath, event.Name)
+	case <-time.After(time.Second * 2):
+		assert.FailNow(t, "no event received for file created in auto-registered directory")
+	}
+
+	assert.Nil(t, w.Close())
+}
+
+func TestAddRecursiveRespectsMaxDepthAndIgnoreFunc(t *testing.T) {
+	setup(t)
+	defer teardown(t)
+
+	ignoredDir := filepath.Clean(TestfolderPath + "/node_modules")
+	shallowDir := filepath.Clean(TestfolderPath + "/a")
+	deepDir := filepath.Clean(TestfolderPath + "/a/b")
+
+	assert.Nil(t, os.Mkdir(ignoredDir, os.ModePerm))
+	assert.Nil(t, os.MkdirAll(deepDir, os.ModePerm))
+
+	w, err := NewWatcher(100 * time.Millisecond)
+	assert.Nil(t, err)
+	defer w.Close()
+
+	w.MaxDepth = 1
+	w.IgnoreFunc = func(path string) bool {
+		return path == ignoredDir
+	}
+
+	assert.Nil(t, w.AddRecursive(TestfolderPath))
+
+	w.recursiveMu.Lock()
+	defer w.recursiveMu.Unlock()
+
+	_, ok := w.recursiveDirs[ignoredDir]
+	assert.False(t, ok, "ignored directory should not be registered")
+
+	_, ok = w.recursiveDirs[shallowDir]
+	assert.True(t, ok, "directory within MaxDepth should be registered")
+
+	_, ok = w.recursiveDirs[deepDir]
+	assert.False(t, ok, "directory beyond MaxDepth should not be registered")
+}
+
+// TestRenamingWatchedSubdirectoryReattributesPendingWrites is a regression
+// test for handleRecursiveRename dropping a pending write instead of moving
+// it: a write debounced under a recursively watched subdirectory must still
+// be emitted, under its new path, if that subdirectory is renamed before the
+// debounce timer fires.
+func TestRenamingWatchedSubdirectoryReattributesPendingWrites(t *testing.T) {
+	setup(t)
+	defer teardown(t)
+
+	subDir := filepath.Clean(TestfolderPath + "/sub")
+	assert.Nil(t, os.Mkdir(subDir, os.ModePerm))
+
+	w, err := NewWatcher(300 * time.Millisecond)
+	assert.Nil(t, err)
+	defer w.Close()
+
+	assert.Nil(t, w.AddRecursive(TestfolderPath))
+
+	filePath := filepath.Clean(subDir + "/file.txt")
+	f, err := os.Create(filePath)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	// Give the write time to enter the debounce map, then rename its parent
+	// directory before the debounce duration elapses.
+	time.Sleep(time.Millisecond * 100)
+
+	renamedDir := filepath.Clean(TestfolderPath + "/sub2")
+	assert.Nil(t, os.Rename(subDir, renamedDir))
+
+	// Besides the reattributed write, the rename also produces the
+	// directory's own bare RENAME notification, and on Linux inotify may
+	// emit an extra, unrelated self-rename event with no resolvable name;
+	// scan past those for the event that matters.
+	renamedPath := filepath.Clean(renamedDir + "/file.txt")
+	deadline := time.After(time.Second * 2)
+	for {
+		select {
+		case event := <-w.Events:
+			if event.Name == renamedPath {
+				return
+			}
+		case <-deadline:
+			assert.FailNow(t, "pending write was dropped instead of re-attributed to the renamed directory")
+		}
+	}
+}
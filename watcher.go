@@ -1,5 +1,3 @@
-// +build !plan9
-
 // Package fswatch provides a platform-independent filewatcher
 // which debounces events to avoid using files before they are entirly
 // written to disk
@@ -14,6 +12,10 @@ import (
 
 const channelBufferSize = 10
 
+// defaultPollInterval is the polling interval New uses when it falls back
+// to NewPollingWatcher because no native backend is available.
+const defaultPollInterval = time.Second
+
 // Watcher is a debounced filewatcher
 // If a CREATE / WRITE happens it waits for {debounceDuration}
 // to publish the event and resets the {debounceDuration} when
@@ -25,47 +27,127 @@ type Watcher struct {
 	// If this is false, only the DELETE event is emitted right after it occurs.
 	IgnoreTemporaryFiles bool
 
+	// MaxDepth limits how many directory levels below an AddRecursive root
+	// are registered, including subdirectories created afterwards. The
+	// default value -1 means no limit.
+	MaxDepth int
+
+	// IgnoreFunc, if set, is consulted by AddRecursive and excludes any
+	// directory (and its subtree) for which it returns true, e.g. to skip
+	// ".git" or "node_modules".
+	IgnoreFunc func(path string) bool
+
 	isClosed bool
 	closeMu  sync.Mutex
 	closeCh  chan struct{}
 
-	watcher          *fsnotify.Watcher
+	watcher          FileWatcher
 	debounceDuration time.Duration
 
 	debounceMap   map[string]chan fsnotify.Event
 	debounceMapMu sync.Mutex
 
+	// recursiveDirs tracks every directory registered through
+	// AddRecursive (or auto-registered afterwards), keyed by path, with
+	// its depth relative to the AddRecursive root it belongs to.
+	recursiveDirs map[string]int
+	recursiveMu   sync.Mutex
+
+	includes []string
+	excludes []string
+	opMask   fsnotify.Op
+	filterMu sync.Mutex
+
+	// DedupByHash, when true, suppresses WRITE/CREATE events whose file
+	// content is unchanged from the last emitted hash for that path (e.g.
+	// a save-on-focus-loss or gofmt run that rewrites identical bytes).
+	DedupByHash bool
+
+	// Hasher computes the content hash used by DedupByHash and attached
+	// to emitted Events via Event.Hash. It defaults to a streaming
+	// SHA-256 of the file's contents.
+	Hasher func(path string) ([]byte, error)
+
+	hashCache *hashLRU
+	hashMu    sync.Mutex
+
+	// AtomicSaveMode, when true, coalesces the write-tmpfile-then-rename
+	// sequence used by editors like vim, IntelliJ and VSCode into a single
+	// WRITE-equivalent Event for the final filename, instead of emitting
+	// the transient events for the temporary file.
+	AtomicSaveMode bool
+
+	pendingRenames map[string]pendingRename
+	renameMu       sync.Mutex
+
+	// pendingDirRenames tracks recursively watched directories renamed away
+	// from their location, awaiting a paired CREATE at their destination so
+	// pending debounces for files beneath them can be re-attributed there;
+	// see handleRecursiveRename and maybeResolveDirRename.
+	pendingDirRenames map[string]pendingDirRename
+	dirRenameMu       sync.Mutex
+
 	// Events is the channel on which all events are published
-	Events chan fsnotify.Event
+	Events chan Event
 
 	// Errors is the channel on which all errors are published
 	Errors chan error
 }
 
-// NewWatcher creates a new watcher with the specified debounceDuration.
+// NewWatcher creates a new watcher backed by the native OS filesystem
+// notification API with the specified debounceDuration. It fails if no
+// native backend is available (e.g. on plan9); use New to fall back to
+// polling in that case.
 func NewWatcher(debounceDuration time.Duration) (*Watcher, error) {
-	// Create underlying fsnotify watcher
-	watcher, err := fsnotify.NewWatcher()
+	watcher, err := newNativeWatcher()
 	if err != nil {
 		return nil, err
 	}
 
+	return newWatcher(watcher, debounceDuration), nil
+}
+
+// New creates a new watcher with the specified debounceDuration, preferring
+// the native OS filesystem notification backend and transparently falling
+// back to a polling backend (ticking every pollInterval) when the native
+// backend is unavailable, e.g. on plan9 or filesystems where it is
+// unreliable (NFS, SMB, fuse).
+func New(debounceDuration, pollInterval time.Duration) (*Watcher, error) {
+	watcher, err := newNativeWatcher()
+	if err != nil {
+		if pollInterval <= 0 {
+			pollInterval = defaultPollInterval
+		}
+
+		watcher, err = NewPollingWatcher(pollInterval)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newWatcher(watcher, debounceDuration), nil
+}
+
+// newWatcher wires up a Watcher on top of an already-constructed backend.
+func newWatcher(watcher FileWatcher, debounceDuration time.Duration) *Watcher {
 	debouncedWatcher := &Watcher{
 		watcher:          watcher,
 		debounceDuration: debounceDuration,
 		debounceMap:      make(map[string]chan fsnotify.Event),
+		recursiveDirs:    make(map[string]int),
 
 		closeCh: make(chan struct{}),
-		Events:  make(chan fsnotify.Event),
+		Events:  make(chan Event),
 		Errors:  make(chan error),
 
 		IgnoreTemporaryFiles: true,
+		MaxDepth:             -1,
 	}
 
 	// Start debounce loop
 	go debouncedWatcher.debounceLoop()
 
-	return debouncedWatcher, nil
+	return debouncedWatcher
 }
 
 // Add adds a new path to the watcher.
@@ -99,10 +181,10 @@ func (w *Watcher) debounceLoop() {
 		case <-w.closeCh:
 			// Watcher was closed
 			return
-		case event := <-w.watcher.Events:
+		case event := <-w.watcher.Events():
 			// A new filesystem event was received
 			w.handleEvent(event)
-		case err := <-w.watcher.Errors:
+		case err := <-w.watcher.Errors():
 			// A fsnotfiy error was received
 			w.Errors <- err
 		}
@@ -111,6 +193,39 @@ func (w *Watcher) debounceLoop() {
 
 // handleEvent handles incoming fsnotify events.
 func (w *Watcher) handleEvent(event fsnotify.Event) {
+	if isCreate(event) {
+		w.maybeWatchNewDir(event)
+		w.maybeResolveDirRename(event)
+	} else if isRemove(event) {
+		w.maybeUnwatchRemovedDir(event)
+	} else if isRename(event) {
+		w.handleRecursiveRename(event)
+	}
+
+	if w.AtomicSaveMode {
+		if isRename(event) {
+			// Track the rename for pairing with the CREATE at its
+			// destination instead of falling through to the legacy
+			// rename handling below, which would forward this event
+			// into the source's debounce channel and race the pairing
+			// in reattributeDebounce.
+			w.trackPendingRename(event)
+			return
+		} else if isCreate(event) {
+			if source, ok := w.resolvePendingRename(event); ok {
+				// This CREATE is the destination half of an atomic-save
+				// rename; coalesce it with any debounced write on the
+				// source path instead of tracking it separately.
+				w.reattributeDebounce(source, event.Name)
+				return
+			}
+		}
+	}
+
+	if !w.passesFilter(event) {
+		return
+	}
+
 	// Handle write or create event
 	if isWrite(event) || isCreate(event) {
 		// Check if file is already being debounced
@@ -140,7 +255,7 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 			ch <- event
 		} else {
 			// Publish the event
-			w.Events <- event
+			w.Events <- Event{Event: event}
 		}
 	}
 }
@@ -158,7 +273,7 @@ func (w *Watcher) debounceFile(event fsnotify.Event, ch chan fsnotify.Event) {
 				if !w.IgnoreTemporaryFiles {
 					// if temporary files are not ignored
 					// publish the delete event of the file
-					w.Events <- newEvent
+					w.Events <- Event{Event: newEvent}
 				}
 
 				return
@@ -172,6 +287,11 @@ func (w *Watcher) debounceFile(event fsnotify.Event, ch chan fsnotify.Event) {
 				return
 			}
 
+			// Keep the most recent event so that, e.g., AtomicSaveMode's
+			// reattributeDebounce can move this goroutine onto a renamed
+			// destination path before it finally fires.
+			event = newEvent
+
 			continue
 
 		case <-time.After(w.debounceDuration):
@@ -179,8 +299,13 @@ func (w *Watcher) debounceFile(event fsnotify.Event, ch chan fsnotify.Event) {
 			w.debounceMapMu.Lock()
 			delete(w.debounceMap, event.Name)
 			w.debounceMapMu.Unlock()
-			// Emit event
-			w.Events <- event
+
+			// Emit event, computing its content hash first if DedupByHash
+			// is enabled; an unchanged hash suppresses the event entirely.
+			hash, emit := w.hashEvent(event)
+			if emit {
+				w.Events <- Event{Event: event, Hash: hash}
+			}
 
 			return
 		}
@@ -194,3 +319,11 @@ func isWrite(event fsnotify.Event) bool {
 func isCreate(event fsnotify.Event) bool {
 	return event.Op&fsnotify.Create == fsnotify.Create
 }
+
+func isRemove(event fsnotify.Event) bool {
+	return event.Op&fsnotify.Remove == fsnotify.Remove
+}
+
+func isRename(event fsnotify.Event) bool {
+	return event.Op&fsnotify.Rename == fsnotify.Rename
+}